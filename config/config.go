@@ -3,9 +3,16 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -100,12 +107,12 @@ type ForkInfo struct {
 }
 
 type TimeScale struct {
-	Host     string `json:"host"`
-	Port     int    `json:"port"`
-	UserName string `json:"user_name"`
-	Password string `json:"password"`
-	Database string `json:"database"`
-	Sslmode  string `json:"sslmode"`
+	Host     string     `json:"host"`
+	Port     int        `json:"port"`
+	UserName string     `json:"user_name"`
+	Password *SecretRef `json:"password"`
+	Database string     `json:"database"`
+	Sslmode  string     `json:"sslmode"`
 
 	// -1 sets it to infinite
 	MaxOpenConns    int `json:"max_open_conns"`
@@ -116,6 +123,27 @@ type TimeScale struct {
 	// If DDL mismatch for aggregates is detected exit with error instead of resetting
 	// the aggregates. Implies `NoAutoUpdateDDL`
 	NoAutoUpdateAggregatesDDL bool `json:"no_auto_update_aggregates_ddl"`
+
+	// Retention declares per-table (or per-table-group) pruning policies for
+	// raw event data. See internal/db/retention for how these are validated
+	// against the schema and enforced.
+	Retention []RetentionPolicy `json:"retention"`
+}
+
+type RetentionPolicy struct {
+	Name   string   `json:"name"`
+	Tables []string `json:"tables"`
+
+	// Duration is how long raw rows are kept before being pruned.
+	Duration Duration `json:"duration"`
+
+	// AggregateInto optionally names a continuous aggregate for the pruned
+	// data; the policy is rejected at startup if no table or view of that
+	// name exists. How far the aggregate's data extends is not checked.
+	AggregateInto string `json:"aggregate_into"`
+
+	// DryRun logs what would be deleted instead of deleting it.
+	DryRun bool `json:"dry_run"`
 }
 
 type Websockets struct {
@@ -211,9 +239,30 @@ func MustLoadConfigFiles(colonSeparatedFilenames string, c *Config) {
 }
 
 func mustLoadConfigFile(path string, c *Config) {
+	if err := loadConfigFile(path, c); err != nil {
+		logger.FatalE(err, "Exit on configuration file unavailable or malformed")
+	}
+}
+
+// loadConfigFiles is the non-fatal counterpart of MustLoadConfigFiles, used
+// by Reload so a bad file rejects the reload instead of killing the process.
+func loadConfigFiles(colonSeparatedFilenames string, c *Config) error {
+	if colonSeparatedFilenames == "" || colonSeparatedFilenames == "null" {
+		return nil
+	}
+
+	for _, filename := range strings.Split(colonSeparatedFilenames, ":") {
+		if err := loadConfigFile(filename, c); err != nil {
+			return fmt.Errorf("loading %s: %w", filename, err)
+		}
+	}
+	return nil
+}
+
+func loadConfigFile(path string, c *Config) error {
 	f, err := os.Open(path)
 	if err != nil {
-		logger.FatalE(err, "Exit on configuration file unavailable")
+		return err
 	}
 	defer f.Close()
 
@@ -222,9 +271,7 @@ func mustLoadConfigFile(path string, c *Config) {
 	// prevent config not used due typos
 	dec.DisallowUnknownFields()
 
-	if err := dec.Decode(&c); err != nil {
-		logger.FatalE(err, "Exit on malformed configuration")
-	}
+	return dec.Decode(&c)
 }
 
 func LogAndcheckUrls(c *Config) {
@@ -243,8 +290,34 @@ func LogAndcheckUrls(c *Config) {
 	}
 }
 
-// Not thread safe, it is written once, then only read
-var Global Config = defaultConfig
+// RedactedString renders c as indented JSON with secret fields (currently
+// TimeScale.Password) replaced by "***", safe to log or print.
+func (c Config) RedactedString() (string, error) {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+var global atomic.Pointer[Config]
+
+func init() {
+	initial := defaultConfig
+	global.Store(&initial)
+}
+
+// Current returns the active configuration. It's safe for concurrent use: a
+// SIGHUP or a POST /internal/config/reload may swap the pointer out from
+// under callers at any time, so take a local copy (`c := *config.Current()`)
+// if you need a consistent snapshot across several field reads.
+func Current() *Config {
+	return global.Load()
+}
+
+// loadedFilenames is the colon-separated file list ReadGlobalFrom was called
+// with, so Reload can re-read the same files.
+var loadedFilenames string
 
 func readConfigFrom(filenames string) Config {
 	var ret Config = defaultConfig
@@ -261,14 +334,39 @@ func readConfigFrom(filenames string) Config {
 	return ret
 }
 
+// loadConfigFrom is the non-fatal counterpart of readConfigFrom, used by
+// Reload: a malformed file or env var rejects the reload instead of exiting
+// the process.
+func loadConfigFrom(filenames string) (Config, error) {
+	ret := defaultConfig
+	if err := loadConfigFiles(filenames, &ret); err != nil {
+		return Config{}, err
+	}
+	if err := envconfig.Process("midgard", &ret); err != nil {
+		return Config{}, err
+	}
+	return ret, nil
+}
+
 // filenames is a colon separated list of files.
 // Values in later files overwrite values from earlier files.
 func ReadGlobalFrom(filenames string) {
-	Global = readConfigFrom(filenames)
-	midlog.SetFromConfig(Global.Logs)
+	loadedFilenames = filenames
+	cfg := readConfigFrom(filenames)
+	global.Store(&cfg)
+	midlog.SetFromConfig(cfg.Logs)
 }
 
 func ReadGlobal() {
+	if len(os.Args) >= 2 && os.Args[1] == "dump" {
+		filenames := ""
+		if len(os.Args) >= 3 {
+			filenames = os.Args[2]
+		}
+		runConfigDump(filenames)
+		os.Exit(0)
+	}
+
 	switch len(os.Args) {
 	case 1:
 		ReadGlobalFrom("")
@@ -278,3 +376,156 @@ func ReadGlobal() {
 		logger.Fatal("One optional configuration file argument only-no flags")
 	}
 }
+
+// runConfigDump implements the `dump [config-files]` subcommand: it loads
+// the effective configuration without installing it as Current, then prints
+// it to stdout with secret fields redacted.
+func runConfigDump(filenames string) {
+	cfg, err := loadConfigFrom(filenames)
+	if err != nil {
+		logger.FatalE(err, "Exit on malformed configuration")
+	}
+
+	redacted, err := cfg.RedactedString()
+	if err != nil {
+		logger.FatalE(err, "Exit on failure to render configuration")
+	}
+
+	fmt.Println(redacted)
+}
+
+// ReloadFunc is called after a successful Reload, with the configuration
+// before and after the swap. Subsystems that hold onto config-derived state
+// (midlog, the websocket connection limiter) register one to react to a
+// reloadable field changing without a full restart.
+type ReloadFunc func(old, next *Config)
+
+var (
+	reloadCallbacksMu sync.Mutex
+	reloadCallbacks   []ReloadFunc
+)
+
+// OnReload registers fn to run after every successful Reload.
+func OnReload(fn ReloadFunc) {
+	reloadCallbacksMu.Lock()
+	defer reloadCallbacksMu.Unlock()
+	reloadCallbacks = append(reloadCallbacks, fn)
+}
+
+// immutableFieldsChanged compares old and next, returning the dotted names
+// of any fields that changed outside the explicit reload allowlist: Logs,
+// UsdPools, ThorChain.ProxiedWhitelistedEndpoints, MaxBlockAge,
+// EventRecorder, and Websockets.ConnectionLimit may change on reload;
+// everything else (DB creds, listen port, ThorChain URLs, BlockStore paths,
+// ...) must stay put, since those are baked into already-running
+// connections, fetchers and caches.
+func immutableFieldsChanged(old, next *Config) []string {
+	var changed []string
+	check := func(name string, equal bool) {
+		if !equal {
+			changed = append(changed, name)
+		}
+	}
+
+	check("listen_port", old.ListenPort == next.ListenPort)
+	check("shutdown_timeout", old.ShutdownTimeout == next.ShutdownTimeout)
+	check("read_timeout", old.ReadTimeout == next.ReadTimeout)
+	check("write_timeout", old.WriteTimeout == next.WriteTimeout)
+
+	check("thorchain.tendermint_url", old.ThorChain.TendermintURL == next.ThorChain.TendermintURL)
+	check("thorchain.thornode_url", old.ThorChain.ThorNodeURL == next.ThorChain.ThorNodeURL)
+	check("thorchain.fetch_batch_size", old.ThorChain.FetchBatchSize == next.ThorChain.FetchBatchSize)
+	check("thorchain.parallelism", old.ThorChain.Parallelism == next.ThorChain.Parallelism)
+	check("thorchain.read_timeout", old.ThorChain.ReadTimeout == next.ThorChain.ReadTimeout)
+	check("thorchain.last_chain_backoff", old.ThorChain.LastChainBackoff == next.ThorChain.LastChainBackoff)
+	check("thorchain.fork_infos", reflect.DeepEqual(old.ThorChain.ForkInfos, next.ThorChain.ForkInfos))
+
+	check("blockstore", reflect.DeepEqual(old.BlockStore, next.BlockStore))
+
+	// Compared field-by-field rather than via reflect.DeepEqual(TimeScale):
+	// TimeScale.Password is a *SecretRef carrying memoization state (a
+	// sync.Once and a cached resolved value) that's populated the first time
+	// anything resolves the DB password, but not yet populated on a
+	// freshly-parsed `next`. DeepEqual-ing that state would make a reload
+	// look like a change forever after the password is first resolved, even
+	// when the configured reference didn't change.
+	check("timescale.host", old.TimeScale.Host == next.TimeScale.Host)
+	check("timescale.port", old.TimeScale.Port == next.TimeScale.Port)
+	check("timescale.user_name", old.TimeScale.UserName == next.TimeScale.UserName)
+	check("timescale.password", secretRefsEqual(old.TimeScale.Password, next.TimeScale.Password))
+	check("timescale.database", old.TimeScale.Database == next.TimeScale.Database)
+	check("timescale.sslmode", old.TimeScale.Sslmode == next.TimeScale.Sslmode)
+	check("timescale.max_open_conns", old.TimeScale.MaxOpenConns == next.TimeScale.MaxOpenConns)
+	check("timescale.commit_batch_size", old.TimeScale.CommitBatchSize == next.TimeScale.CommitBatchSize)
+	check("timescale.no_auto_update_ddl", old.TimeScale.NoAutoUpdateDDL == next.TimeScale.NoAutoUpdateDDL)
+	check("timescale.no_auto_update_aggregates_ddl",
+		old.TimeScale.NoAutoUpdateAggregatesDDL == next.TimeScale.NoAutoUpdateAggregatesDDL)
+	check("timescale.retention", reflect.DeepEqual(old.TimeScale.Retention, next.TimeScale.Retention))
+
+	check("websockets.enable", old.Websockets.Enable == next.Websockets.Enable)
+
+	check("case_insensitive_chains", reflect.DeepEqual(old.CaseInsensitiveChains, next.CaseInsensitiveChains))
+
+	return changed
+}
+
+// Reload re-reads the same files/env used at startup. It rejects the new
+// configuration with an error naming every changed field if anything outside
+// immutableFieldsChanged's allowlist changed, leaving the currently active
+// configuration untouched; otherwise it atomically swaps the new
+// configuration in and invokes every OnReload callback.
+func Reload() error {
+	next, err := loadConfigFrom(loadedFilenames)
+	if err != nil {
+		return fmt.Errorf("config reload: %w", err)
+	}
+
+	old := Current()
+	if changed := immutableFieldsChanged(old, &next); len(changed) > 0 {
+		return fmt.Errorf("config reload: refusing to change immutable field(s): %s", strings.Join(changed, ", "))
+	}
+
+	global.Store(&next)
+	midlog.SetFromConfig(next.Logs)
+
+	reloadCallbacksMu.Lock()
+	callbacks := append([]ReloadFunc(nil), reloadCallbacks...)
+	reloadCallbacksMu.Unlock()
+	for _, fn := range callbacks {
+		fn(old, &next)
+	}
+
+	return nil
+}
+
+// WatchSIGHUP installs a signal handler that calls Reload on SIGHUP and logs
+// the outcome. Call it once at startup, after the first ReadGlobal(From).
+func WatchSIGHUP() {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		for range sig {
+			if err := Reload(); err != nil {
+				logger.ErrorF("Config reload rejected: %v", err)
+				continue
+			}
+			logger.Info("Config reloaded")
+		}
+	}()
+}
+
+// ReloadHandler serves POST /internal/config/reload, triggering the same
+// reload as SIGHUP. Mount it only on a local-only, operator-facing admin
+// listener (alongside the internal/db/retention admin endpoints) since a
+// reload re-reads files from local disk.
+func ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := Reload(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("writing config file: %v", err)
+	}
+	return path
+}
+
+const baseConfigJSON = `{
+	"listen_port": 8080,
+	"usdpools": ["BNB.BUSD-BD1"]
+}`
+
+func TestReloadAcceptsPartialReload(t *testing.T) {
+	path := writeConfigFile(t, baseConfigJSON)
+	ReadGlobalFrom(path)
+
+	if err := os.WriteFile(path, []byte(`{
+		"listen_port": 8080,
+		"usdpools": ["BNB.BUSD-BD1", "ETH.USDT-0XDAC17F958D2EE523A2206206994597C13D831EC7"]
+	}`), 0o644); err != nil {
+		t.Fatalf("rewriting config file: %v", err)
+	}
+
+	if err := Reload(); err != nil {
+		t.Fatalf("expected reload of a reloadable field (usdpools) to succeed, got: %v", err)
+	}
+
+	if got := Current().UsdPools; len(got) != 2 {
+		t.Fatalf("expected reloaded UsdPools to have 2 entries, got %v", got)
+	}
+}
+
+func TestReloadRejectsImmutableFieldChange(t *testing.T) {
+	path := writeConfigFile(t, baseConfigJSON)
+	ReadGlobalFrom(path)
+	before := *Current()
+
+	if err := os.WriteFile(path, []byte(`{
+		"listen_port": 9090,
+		"usdpools": ["BNB.BUSD-BD1"]
+	}`), 0o644); err != nil {
+		t.Fatalf("rewriting config file: %v", err)
+	}
+
+	err := Reload()
+	if err == nil {
+		t.Fatalf("expected reload changing listen_port (immutable) to be rejected")
+	}
+
+	if got := *Current(); got.ListenPort != before.ListenPort {
+		t.Fatalf("expected config to be left intact after a rejected reload, ListenPort changed to %d", got.ListenPort)
+	}
+}
+
+func TestReloadToleratesAlreadyResolvedSecretRef(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"listen_port": 8080,
+		"usdpools": ["BNB.BUSD-BD1"],
+		"timescale": {"password": "hunter2"}
+	}`)
+	ReadGlobalFrom(path)
+
+	// Simulate a real deployment resolving the DB password once at startup
+	// (e.g. to open the connection pool) before any reload ever happens.
+	if _, err := Current().TimeScale.Password.Value(); err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+
+	if err := Reload(); err != nil {
+		t.Fatalf("expected a reload with an unchanged password reference to succeed, got: %v", err)
+	}
+}
+
+func TestReloadRejectsChangedSecretRef(t *testing.T) {
+	path := writeConfigFile(t, `{
+		"listen_port": 8080,
+		"usdpools": ["BNB.BUSD-BD1"],
+		"timescale": {"password": "hunter2"}
+	}`)
+	ReadGlobalFrom(path)
+
+	if err := os.WriteFile(path, []byte(`{
+		"listen_port": 8080,
+		"usdpools": ["BNB.BUSD-BD1"],
+		"timescale": {"password": "different-password"}
+	}`), 0o644); err != nil {
+		t.Fatalf("rewriting config file: %v", err)
+	}
+
+	if err := Reload(); err == nil {
+		t.Fatalf("expected reload changing timescale.password (immutable) to be rejected")
+	}
+}
+
+func TestReloadLeavesConfigIntactOnMalformedFile(t *testing.T) {
+	path := writeConfigFile(t, baseConfigJSON)
+	ReadGlobalFrom(path)
+	before := *Current()
+
+	if err := os.WriteFile(path, []byte(`{ not valid json`), 0o644); err != nil {
+		t.Fatalf("rewriting config file: %v", err)
+	}
+
+	err := Reload()
+	if err == nil {
+		t.Fatalf("expected reload of a malformed file to fail")
+	}
+
+	if got := *Current(); got.ListenPort != before.ListenPort || len(got.UsdPools) != len(before.UsdPools) {
+		t.Fatalf("expected config to be left intact after a malformed reload, got %+v", got)
+	}
+}
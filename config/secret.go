@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// SecretRef holds a secret value that, instead of living in the config file
+// or environment variable as plaintext, can reference where to fetch it
+// from: a file on disk, another environment variable, or the stdout of a
+// command run once at startup. This lets operators drop Midgard into
+// Kubernetes/Vault/Docker-secret workflows without baking credentials into
+// config files or environment variables.
+//
+// The raw value is one of:
+//   - a literal string, used as-is
+//   - "file:/path/to/secret", read and trimmed of trailing newline
+//   - "env:VAR_NAME", the value of another environment variable
+//   - "exec:/path/to/cmd", the trimmed stdout of running the command
+//
+// Call Value() to get the resolved secret; it's memoized after first use.
+//
+// SecretRef contains a sync.Once and must only ever be used through a
+// *SecretRef (TimeScale.Password is declared as a pointer for this reason) —
+// never embedded or assigned by value, which would copy the lock along with
+// whatever it was protecting.
+type SecretRef struct {
+	raw string
+
+	once       sync.Once
+	resolved   string
+	resolveErr error
+}
+
+func (s *SecretRef) UnmarshalJSON(b []byte) error {
+	var raw string
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	s.raw = raw
+	return nil
+}
+
+// MarshalJSON always redacts the secret, so a SecretRef never leaks through
+// Config.RedactedString or any other place Config gets JSON-encoded. Pointer
+// receiver so marshaling never copies the embedded sync.Once.
+func (s *SecretRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal("***")
+}
+
+// Decode implements envconfig.Decoder so SecretRef plugs into the existing
+// envconfig.Process(...) call without callers having to change.
+func (s *SecretRef) Decode(value string) error {
+	s.raw = value
+	return nil
+}
+
+// Value resolves and returns the secret, memoizing the result (including
+// any error) on first call.
+func (s *SecretRef) Value() (string, error) {
+	s.once.Do(func() {
+		s.resolved, s.resolveErr = resolveSecretRef(s.raw)
+	})
+	return s.resolved, s.resolveErr
+}
+
+// secretRefsEqual reports whether a and b reference the same secret, by
+// comparing their configured reference (the raw literal/"file:"/"env:"/
+// "exec:" string) rather than any cached, resolved value — used by
+// immutableFieldsChanged so a reload isn't rejected just because the old
+// SecretRef has already been resolved once. It treats a nil SecretRef the
+// same as one with an empty reference.
+func secretRefsEqual(a, b *SecretRef) bool {
+	var araw, braw string
+	if a != nil {
+		araw = a.raw
+	}
+	if b != nil {
+		braw = b.raw
+	}
+	return araw == braw
+}
+
+func resolveSecretRef(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "file:"):
+		path := strings.TrimPrefix(raw, "file:")
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("secret: reading %s: %w", path, err)
+		}
+		return strings.TrimRight(string(b), "\n"), nil
+
+	case strings.HasPrefix(raw, "env:"):
+		name := strings.TrimPrefix(raw, "env:")
+		v, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("secret: environment variable %s is not set", name)
+		}
+		return v, nil
+
+	case strings.HasPrefix(raw, "exec:"):
+		path := strings.TrimPrefix(raw, "exec:")
+		out, err := exec.Command(path).Output()
+		if err != nil {
+			return "", fmt.Errorf("secret: running %s: %w", path, err)
+		}
+		return strings.TrimRight(string(out), "\n"), nil
+
+	default:
+		return raw, nil
+	}
+}
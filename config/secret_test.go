@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func secretFromJSON(t *testing.T, raw string) *SecretRef {
+	t.Helper()
+	var s SecretRef
+	if err := json.Unmarshal([]byte(`"`+raw+`"`), &s); err != nil {
+		t.Fatalf("unmarshaling %q: %v", raw, err)
+	}
+	return &s
+}
+
+func TestSecretRefLiteral(t *testing.T) {
+	s := secretFromJSON(t, "hunter2")
+
+	v, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+	if v != "hunter2" {
+		t.Fatalf("expected literal value, got %q", v)
+	}
+}
+
+func TestSecretRefFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatalf("writing secret file: %v", err)
+	}
+
+	s := secretFromJSON(t, "file:"+path)
+
+	v, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+	if v != "from-file" {
+		t.Fatalf("expected trailing newline to be trimmed, got %q", v)
+	}
+}
+
+func TestSecretRefEnv(t *testing.T) {
+	t.Setenv("MIDGARD_TEST_SECRET", "from-env")
+
+	s := secretFromJSON(t, "env:MIDGARD_TEST_SECRET")
+
+	v, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+	if v != "from-env" {
+		t.Fatalf("expected env value, got %q", v)
+	}
+}
+
+func TestSecretRefValueIsMemoized(t *testing.T) {
+	s := secretFromJSON(t, "hunter2")
+
+	first, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+
+	s.raw = "changed-after-first-call"
+	second, err := s.Value()
+	if err != nil {
+		t.Fatalf("Value(): %v", err)
+	}
+
+	if first != second {
+		t.Fatalf("expected memoized value %q, got %q", first, second)
+	}
+}
+
+func TestSecretRefMarshalJSONRedacts(t *testing.T) {
+	s := secretFromJSON(t, "hunter2")
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(b) != `"***"` {
+		t.Fatalf("expected secret to be redacted, got %s", b)
+	}
+}
@@ -0,0 +1,90 @@
+// Package db holds the shared TimeScale/Postgres connection pool and the
+// thin query helpers built on top of it.
+package db
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"gitlab.com/thorchain/midgard/config"
+)
+
+var pool *pgxpool.Pool
+
+// Setup opens the TimeScale connection pool that Query, Exec and
+// TableExists use. It must be called once at startup, before any of those are
+// used. config.Reload rejects any change to TimeScale, so it never needs to
+// run again against different settings.
+func Setup(ctx context.Context, cfg config.TimeScale) error {
+	password := ""
+	if cfg.Password != nil {
+		v, err := cfg.Password.Value()
+		if err != nil {
+			return fmt.Errorf("db: resolving TimeScale.Password: %w", err)
+		}
+		password = v
+	}
+
+	// Built as a URL (rather than interpolated into a "key=value ..." DSN
+	// string) so a password containing spaces or "key=value"-shaped tokens
+	// gets percent-encoded instead of truncating or injecting other
+	// connection parameters during parsing.
+	connURL := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.UserName, password),
+		Host:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:     "/" + cfg.Database,
+		RawQuery: url.Values{"sslmode": {cfg.Sslmode}}.Encode(),
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(connURL.String())
+	if err != nil {
+		return fmt.Errorf("db: parsing TimeScale connection string: %w", err)
+	}
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	}
+
+	p, err := pgxpool.ConnectConfig(ctx, poolConfig)
+	if err != nil {
+		return fmt.Errorf("db: connecting to TimeScale: %w", err)
+	}
+
+	pool = p
+	return nil
+}
+
+// Query runs sql against the TimeScale pool.
+func Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return pool.Query(ctx, sql, args...)
+}
+
+// Exec runs sql against the TimeScale pool and returns the number of rows
+// affected.
+func Exec(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+	tag, err := pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// TableExists reports whether table is a known table or view in the public
+// schema.
+func TableExists(ctx context.Context, table string) (bool, error) {
+	const q = `
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.tables
+			WHERE table_schema = 'public' AND table_name = $1
+		)
+	`
+	var exists bool
+	row := pool.QueryRow(ctx, q, table)
+	if err := row.Scan(&exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
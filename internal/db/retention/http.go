@@ -0,0 +1,50 @@
+package retention
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// RunHandler serves POST /internal/retention/run?policy=<name>, triggering an
+// immediate, synchronous run of the named policy.
+//
+// It's intended to be mounted on the operator-only admin mux alongside other
+// `/internal/...` endpoints, not on the public API listener.
+func RunHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("policy")
+	if name == "" {
+		http.Error(w, "missing required query parameter: policy", http.StatusBadRequest)
+		return
+	}
+
+	status, err := Run(r.Context(), name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	writeJSON(w, status)
+}
+
+// StatusHandler serves GET /internal/retention/status, returning every
+// registered policy's last-run time, rows deleted, and next scheduled run.
+func StatusHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, StatusAll())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
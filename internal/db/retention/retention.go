@@ -0,0 +1,259 @@
+// Package retention enforces the raw-event-table pruning policies declared
+// in config.TimeScale.Retention: it validates them against the schema at
+// startup, then periodically deletes rows older than each policy's Duration
+// in small chunks so pruning never holds a long lock or bloats the WAL.
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gitlab.com/thorchain/midgard/config"
+	"gitlab.com/thorchain/midgard/internal/db"
+	"gitlab.com/thorchain/midgard/internal/util/midlog"
+)
+
+// tableExists, dbQuery and dbExec are package vars rather than direct calls
+// to db.TableExists/db.Query/db.Exec so tests can exercise Validate and
+// pruneTable's chunking logic against a fake without a real TimeScale pool.
+var (
+	tableExists = db.TableExists
+	dbQuery     = db.Query
+	dbExec      = db.Exec
+)
+
+// deleteChunkSize caps how many rows a single DELETE removes, so a policy
+// run never takes a long-lived lock on a large table.
+const deleteChunkSize = 10000
+
+// scanInterval is how often the background loop runs every registered
+// policy. It's independent of any individual policy's retention Duration.
+const scanInterval = 5 * time.Minute
+
+var logger = midlog.LoggerForModule("retention")
+
+var rowsPrunedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "midgard_retention_rows_pruned_total",
+	Help: "Rows deleted by retention policies, partitioned by policy and table.",
+}, []string{"policy", "table"})
+
+// Status reports the outcome of the most recent run of a policy.
+type Status struct {
+	LastRun     time.Time
+	NextRun     time.Time
+	RowsDeleted int64
+	Err         string
+}
+
+type registeredPolicy struct {
+	config.RetentionPolicy
+
+	mu     sync.Mutex
+	status Status
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*registeredPolicy{}
+)
+
+// Validate checks policies against the actual schema: every referenced table
+// (and, if set, AggregateInto) must exist. It does not mutate the registry.
+func Validate(ctx context.Context, policies []config.RetentionPolicy) error {
+	seen := map[string]bool{}
+	for _, p := range policies {
+		if seen[p.Name] {
+			return fmt.Errorf("retention: duplicate policy name %q", p.Name)
+		}
+		seen[p.Name] = true
+
+		if len(p.Tables) == 0 {
+			return fmt.Errorf("retention: policy %q declares no tables", p.Name)
+		}
+
+		for _, table := range p.Tables {
+			exists, err := tableExists(ctx, table)
+			if err != nil {
+				return fmt.Errorf("retention: checking table %q for policy %q: %w", table, p.Name, err)
+			}
+			if !exists {
+				return fmt.Errorf("retention: policy %q references unknown table %q", p.Name, table)
+			}
+		}
+
+		if p.AggregateInto != "" {
+			exists, err := tableExists(ctx, p.AggregateInto)
+			if err != nil {
+				return fmt.Errorf("retention: checking aggregate %q for policy %q: %w", p.AggregateInto, p.Name, err)
+			}
+			if !exists {
+				return fmt.Errorf(
+					"retention: policy %q names AggregateInto %q which doesn't exist; "+
+						"refusing to prune raw rows an aggregate can't already cover", p.Name, p.AggregateInto)
+			}
+		}
+	}
+	return nil
+}
+
+// Register validates policies and installs them as the active set, replacing
+// whatever was registered before. Call it once at startup after Validate (or
+// let Register validate for you).
+func Register(ctx context.Context, policies []config.RetentionPolicy) error {
+	if err := Validate(ctx, policies); err != nil {
+		return err
+	}
+
+	next := make(map[string]*registeredPolicy, len(policies))
+	for _, p := range policies {
+		next[p.Name] = &registeredPolicy{RetentionPolicy: p}
+	}
+
+	registryMu.Lock()
+	registry = next
+	registryMu.Unlock()
+
+	return nil
+}
+
+// StartBackground runs every registered policy once per scanInterval, until
+// ctx is done.
+func StartBackground(ctx context.Context) {
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, name := range registeredNames() {
+				if _, err := Run(ctx, name); err != nil {
+					logger.ErrorF("retention policy %q failed: %v", name, err)
+				}
+			}
+		}
+	}
+}
+
+func registeredNames() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+func lookup(name string) (*registeredPolicy, bool) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Run executes a single policy immediately (used both by the background loop
+// and the on-demand admin endpoint) and records its Status.
+func Run(ctx context.Context, name string) (Status, error) {
+	p, ok := lookup(name)
+	if !ok {
+		return Status{}, fmt.Errorf("retention: unknown policy %q", name)
+	}
+
+	cutoff := time.Now().Add(-p.Duration.Value())
+
+	var total int64
+	var runErr error
+	for _, table := range p.Tables {
+		deleted, err := pruneTable(ctx, table, cutoff, p.DryRun)
+		if err != nil {
+			runErr = fmt.Errorf("retention: pruning %q for policy %q: %w", table, p.Name, err)
+			break
+		}
+		total += deleted
+		if !p.DryRun {
+			rowsPrunedTotal.WithLabelValues(p.Name, table).Add(float64(deleted))
+		}
+	}
+
+	p.mu.Lock()
+	p.status = Status{
+		LastRun:     time.Now(),
+		NextRun:     time.Now().Add(scanInterval),
+		RowsDeleted: total,
+	}
+	if runErr != nil {
+		p.status.Err = runErr.Error()
+	}
+	status := p.status
+	p.mu.Unlock()
+
+	return status, runErr
+}
+
+// pruneTable deletes rows older than cutoff from table in chunks of
+// deleteChunkSize, returning the total number of rows deleted (or that would
+// be deleted, if dryRun).
+func pruneTable(ctx context.Context, table string, cutoff time.Time, dryRun bool) (int64, error) {
+	if dryRun {
+		const q = `SELECT count(*) FROM %s WHERE block_timestamp < $1`
+		rows, err := dbQuery(ctx, fmt.Sprintf(q, table), cutoff.UnixNano())
+		if err != nil {
+			return 0, err
+		}
+		defer rows.Close()
+
+		var count int64
+		for rows.Next() {
+			if err := rows.Scan(&count); err != nil {
+				return 0, err
+			}
+		}
+		return count, nil
+	}
+
+	q := fmt.Sprintf(`
+		WITH victims AS (
+			SELECT ctid FROM %s WHERE block_timestamp < $1 LIMIT $2
+		)
+		DELETE FROM %s USING victims WHERE %s.ctid = victims.ctid
+	`, table, table, table)
+
+	var total int64
+	for {
+		deleted, err := dbExec(ctx, q, cutoff.UnixNano(), deleteChunkSize)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+		if deleted < deleteChunkSize {
+			return total, nil
+		}
+	}
+}
+
+// StatusAll returns a snapshot of every registered policy's last-run status.
+func StatusAll() map[string]Status {
+	registryMu.Lock()
+	names := make([]string, 0, len(registry))
+	policies := make([]*registeredPolicy, 0, len(registry))
+	for name, p := range registry {
+		names = append(names, name)
+		policies = append(policies, p)
+	}
+	registryMu.Unlock()
+
+	out := make(map[string]Status, len(names))
+	for i, name := range names {
+		policies[i].mu.Lock()
+		out[name] = policies[i].status
+		policies[i].mu.Unlock()
+	}
+	return out
+}
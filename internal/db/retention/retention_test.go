@@ -0,0 +1,113 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"gitlab.com/thorchain/midgard/config"
+)
+
+func withFakeDB(t *testing.T, exists func(ctx context.Context, table string) (bool, error),
+	query func(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error),
+	exec func(ctx context.Context, sql string, args ...interface{}) (int64, error)) {
+	t.Helper()
+
+	origTableExists, origQuery, origExec := tableExists, dbQuery, dbExec
+	t.Cleanup(func() {
+		tableExists, dbQuery, dbExec = origTableExists, origQuery, origExec
+	})
+
+	if exists != nil {
+		tableExists = exists
+	}
+	if query != nil {
+		dbQuery = query
+	}
+	if exec != nil {
+		dbExec = exec
+	}
+}
+
+func TestValidateRejectsUnknownTable(t *testing.T) {
+	withFakeDB(t, func(ctx context.Context, table string) (bool, error) {
+		return table == "known_table", nil
+	}, nil, nil)
+
+	err := Validate(context.Background(), []config.RetentionPolicy{{
+		Name:   "p",
+		Tables: []string{"unknown_table"},
+	}})
+	if err == nil {
+		t.Fatalf("expected Validate to reject a policy referencing an unknown table")
+	}
+}
+
+func TestValidateAcceptsKnownTables(t *testing.T) {
+	withFakeDB(t, func(ctx context.Context, table string) (bool, error) {
+		return true, nil
+	}, nil, nil)
+
+	err := Validate(context.Background(), []config.RetentionPolicy{{
+		Name:   "p",
+		Tables: []string{"known_table"},
+	}})
+	if err != nil {
+		t.Fatalf("expected Validate to accept a policy whose tables all exist, got: %v", err)
+	}
+}
+
+func TestValidateRejectsUnknownAggregateInto(t *testing.T) {
+	withFakeDB(t, func(ctx context.Context, table string) (bool, error) {
+		return table != "missing_aggregate", nil
+	}, nil, nil)
+
+	err := Validate(context.Background(), []config.RetentionPolicy{{
+		Name:          "p",
+		Tables:        []string{"known_table"},
+		AggregateInto: "missing_aggregate",
+	}})
+	if err == nil {
+		t.Fatalf("expected Validate to reject a policy whose AggregateInto doesn't exist")
+	}
+}
+
+func TestPruneTableStopsAfterAPartialChunk(t *testing.T) {
+	// Two full chunks then a partial one: pruneTable should issue three
+	// DELETEs and stop as soon as a chunk comes back smaller than
+	// deleteChunkSize.
+	calls := 0
+	withFakeDB(t, nil, nil, func(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+		calls++
+		switch calls {
+		case 1, 2:
+			return deleteChunkSize, nil
+		default:
+			return 42, nil
+		}
+	})
+
+	total, err := pruneTable(context.Background(), "known_table", time.Now(), false)
+	if err != nil {
+		t.Fatalf("pruneTable: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected pruneTable to stop after the first partial chunk, got %d DELETEs", calls)
+	}
+	if want := int64(deleteChunkSize*2 + 42); total != want {
+		t.Fatalf("expected total deleted %d, got %d", want, total)
+	}
+}
+
+func TestPruneTableSurfacesExecError(t *testing.T) {
+	wantErr := context.Canceled
+	withFakeDB(t, nil, nil, func(ctx context.Context, sql string, args ...interface{}) (int64, error) {
+		return 0, wantErr
+	})
+
+	_, err := pruneTable(context.Background(), "known_table", time.Now(), false)
+	if err != wantErr {
+		t.Fatalf("expected pruneTable to surface the Exec error, got: %v", err)
+	}
+}
@@ -6,6 +6,14 @@ import (
 	"gitlab.com/thorchain/midgard/internal/db"
 )
 
+// The lookups below assume the following indices exist on
+// thorname_change_events (they make the DISTINCT ON / ORDER BY combinations
+// used here plan as index scans instead of full sorts):
+//
+//   CREATE INDEX ON thorname_change_events (name, block_timestamp DESC);
+//   CREATE INDEX ON thorname_change_events (address);
+//   CREATE INDEX ON thorname_change_events (owner);
+
 type THORNameEntry struct {
 	Chain   string
 	Address string
@@ -17,91 +25,105 @@ type THORName struct {
 	Entries []THORNameEntry
 }
 
-//gets thorname legitimate owner and checks its expire date.
-func CheckTHORName(ctx context.Context, name *string) (tName THORName, err error) {
-	currentHeight, _, _ := LastBlock()
-
-	// Expiration of THORName is tracked only by the "THOR" record. All other
-	// chains follow suit with the status of this "root" record.
-	q := `
-		SELECT
-			expire, owner
-		FROM thorname_change_events
-		WHERE
-			expire > $1 AND name = $2
-		ORDER BY
-			block_timestamp DESC
-		LIMIT 1
-	`
-
-	rows, err := db.Query(ctx, q, currentHeight, name)
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		if err := rows.Scan(&tName.Expire, &tName.Owner); err != nil {
-			return tName, err
-		}
-		break
-	}
-
-	return
-}
-
+// GetTHORName fetches the current owner, expiry and per-chain addresses of a
+// single THORName. It's a thin wrapper around GetTHORNames for callers that
+// only need to look up one name at a time.
 func GetTHORName(ctx context.Context, name *string) (tName THORName, err error) {
-	tName, err = CheckTHORName(ctx, name)
+	names, err := GetTHORNames(ctx, []string{*name})
 	if err != nil {
 		return
 	}
+	return names[*name], nil
+}
 
-	// check if we found a name
-	if tName.Owner == "" {
-		return
-	}
+// GetTHORNames resolves the current owner, expiry and per-chain addresses of
+// a batch of THORNames in a single round trip, so callers building up a
+// response set don't pay per-name query latency.
+func GetTHORNames(ctx context.Context, names []string) (map[string]THORName, error) {
+	currentHeight, _, _ := LastBlock()
 
 	q := `
+		WITH current_owners AS (
+			SELECT DISTINCT ON (name)
+				name, owner, expire
+			FROM thorname_change_events
+			WHERE
+				expire > $1 AND name = ANY($2)
+			ORDER BY
+				name, block_timestamp DESC
+		), current_entries AS (
+			SELECT DISTINCT ON (name, chain)
+				name, chain, address
+			FROM thorname_change_events
+			WHERE
+				name = ANY($2)
+			ORDER BY
+				name, chain, block_timestamp DESC
+		)
 		SELECT
-			DISTINCT on (chain) chain, address
-		FROM thorname_change_events
-		WHERE
-			name = $1
+			current_owners.name, current_owners.owner, current_owners.expire,
+			current_entries.chain, current_entries.address
+		FROM current_owners
+		JOIN current_entries ON current_entries.name = current_owners.name
 		ORDER BY
-			chain, block_timestamp DESC
+			current_owners.name
 	`
 
-	rows, err := db.Query(ctx, q, name)
+	rows, err := db.Query(ctx, q, currentHeight, names)
 	if err != nil {
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
+	result := make(map[string]THORName, len(names))
 	for rows.Next() {
+		var name string
 		var entry THORNameEntry
-		if err := rows.Scan(&entry.Chain, &entry.Address); err != nil {
-			return tName, err
+		tName := THORName{}
+		if err := rows.Scan(&name, &tName.Owner, &tName.Expire, &entry.Chain, &entry.Address); err != nil {
+			return nil, err
 		}
-		tName.Entries = append(tName.Entries, entry)
+		tName.Entries = append(result[name].Entries, entry)
+		result[name] = tName
 	}
 
-	return
+	return result, nil
 }
 
-// NOTE: there is probably a pure-postrgres means of doing this, which would be
-// more performant. If we find that the performance of this query to be too
-// slow, can try that. I don't imagine it being much of a problem since people
-// aren't going to associate their address with 100's of thornames
+// GetTHORNamesByAddress returns the names that currently resolve to addr on
+// any chain. This used to loop over candidate names and re-run GetTHORName
+// (itself two queries) for each one; it's now a single query against the
+// current state of thorname_change_events.
 func GetTHORNamesByAddress(ctx context.Context, addr *string) (names []string, err error) {
+	currentHeight, _, _ := LastBlock()
+
 	q := `
-		SELECT
-			DISTINCT on (name) name
-		FROM thorname_change_events
+		WITH current_owners AS (
+			SELECT DISTINCT ON (name)
+				name, owner, expire
+			FROM thorname_change_events
+			WHERE
+				expire > $1
+			ORDER BY
+				name, block_timestamp DESC
+		), current_entries AS (
+			SELECT DISTINCT ON (name, chain)
+				name, chain, address
+			FROM thorname_change_events
+			ORDER BY
+				name, chain, block_timestamp DESC
+		)
+		SELECT DISTINCT
+			current_owners.name
+		FROM current_owners
+		JOIN current_entries ON current_entries.name = current_owners.name
 		WHERE
-			address = $1
+			current_entries.address = $2
+		ORDER BY
+			current_owners.name
 	`
 
-	rows, err := db.Query(ctx, q, addr)
+	rows, err := db.Query(ctx, q, currentHeight, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -112,33 +134,35 @@ func GetTHORNamesByAddress(ctx context.Context, addr *string) (names []string, e
 		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-
-		// validate the address is associated with the current record of THORname
-		tName, err := GetTHORName(ctx, &name)
-		if err != nil {
-			continue
-		}
-		for _, e := range tName.Entries {
-			if e.Address == *addr {
-				names = append(names, name)
-				break
-			}
-		}
+		names = append(names, name)
 	}
 
 	return
 }
 
 func GetTHORNamesByOwnerAddress(ctx context.Context, addr *string) (names []string, err error) {
+	currentHeight, _, _ := LastBlock()
+
 	q := `
+		WITH current_owners AS (
+			SELECT DISTINCT ON (name)
+				name, owner, expire
+			FROM thorname_change_events
+			WHERE
+				expire > $1
+			ORDER BY
+				name, block_timestamp DESC
+		)
 		SELECT
-			DISTINCT on (name) name
-		FROM thorname_change_events
+			name
+		FROM current_owners
 		WHERE
-			owner = $1
+			owner = $2
+		ORDER BY
+			name
 	`
 
-	rows, err := db.Query(ctx, q, addr)
+	rows, err := db.Query(ctx, q, currentHeight, addr)
 	if err != nil {
 		return nil, err
 	}
@@ -149,15 +173,7 @@ func GetTHORNamesByOwnerAddress(ctx context.Context, addr *string) (names []stri
 		if err := rows.Scan(&name); err != nil {
 			return nil, err
 		}
-
-		tName, err := CheckTHORName(ctx, &name)
-		if err != nil && tName.Owner == "" {
-			continue
-		}
-
-		if tName.Owner == *addr {
-			names = append(names, name)
-		}
+		names = append(names, name)
 	}
 
 	return
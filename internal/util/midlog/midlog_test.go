@@ -0,0 +1,68 @@
+package midlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSetFromConfigDefaultsToTextFormat(t *testing.T) {
+	defer SetFromConfig(LogConfig{})
+
+	SetFromConfig(LogConfig{})
+	var buf bytes.Buffer
+	output = &buf
+
+	LoggerForModule("test").Info("hello")
+
+	line := buf.String()
+	if !strings.Contains(line, "hello") {
+		t.Fatalf("expected the message in the text log line, got %q", line)
+	}
+	if strings.HasPrefix(strings.TrimSpace(line), "{") {
+		t.Fatalf("expected a text line by default, got what looks like JSON: %q", line)
+	}
+}
+
+func TestSetFromConfigJSONFormat(t *testing.T) {
+	defer SetFromConfig(LogConfig{})
+
+	SetFromConfig(LogConfig{Format: "json"})
+	var buf bytes.Buffer
+	output = &buf
+
+	LoggerForModule("test").Info("hello")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if decoded["message"] != "hello" {
+		t.Fatalf("expected a message field, got %v", decoded)
+	}
+	if decoded["module"] != "test" {
+		t.Fatalf("expected a module field, got %v", decoded)
+	}
+}
+
+func TestSetFromConfigFileFormatOverridesTopLevel(t *testing.T) {
+	defer SetFromConfig(LogConfig{})
+
+	SetFromConfig(LogConfig{
+		Format: "text",
+		File: FileConfig{
+			Path:   t.TempDir() + "/midgard.log",
+			Format: "json",
+		},
+	})
+	var buf bytes.Buffer
+	output = &buf
+
+	LoggerForModule("test").Info("hello")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("expected File.Format=json to win over the top-level text format, got %q: %v", buf.String(), err)
+	}
+}
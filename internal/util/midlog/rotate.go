@@ -0,0 +1,195 @@
+package midlog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotatingWriter is an io.Writer that appends to FileConfig.Path, rotating
+// the file once it exceeds MaxSizeMB and pruning archived (rotated) files by
+// count (MaxBackups) and age (MaxAgeDays), optionally gzip-compressing them.
+//
+// It's modeled after the usual lumberjack-style rotating file appender: the
+// active file always has the configured Path; rotated files are renamed to
+// "<path>.<timestamp>" (and "<path>.<timestamp>.gz" if Compress is set).
+type rotatingWriter struct {
+	cfg FileConfig
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func newRotatingWriter(cfg FileConfig) *rotatingWriter {
+	return &rotatingWriter{cfg: cfg}
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file == nil {
+		if err := w.openExisting(); err != nil {
+			return 0, err
+		}
+	}
+
+	maxSize := int64(w.cfg.MaxSizeMB) * 1024 * 1024
+	if maxSize > 0 && w.size+int64(len(p)) > maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *rotatingWriter) openExisting() error {
+	info, err := os.Stat(w.cfg.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		w.size = info.Size()
+	} else {
+		w.size = 0
+	}
+
+	f, err := os.OpenFile(w.cfg.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("midlog: opening log file: %w", err)
+	}
+	w.file = f
+	return nil
+}
+
+func (w *rotatingWriter) rotate() error {
+	if w.file != nil {
+		if err := w.file.Close(); err != nil {
+			return fmt.Errorf("midlog: closing log file before rotation: %w", err)
+		}
+		w.file = nil
+	}
+
+	backupPath := w.cfg.Path + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(w.cfg.Path, backupPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("midlog: rotating log file: %w", err)
+	}
+
+	if w.cfg.Compress {
+		if err := compressFile(backupPath); err != nil {
+			return fmt.Errorf("midlog: compressing rotated log file: %w", err)
+		}
+	}
+
+	if err := w.openExisting(); err != nil {
+		return err
+	}
+
+	return w.prune()
+}
+
+// prune enforces MaxBackups and MaxAgeDays over the archived files next to
+// cfg.Path, deleting the oldest ones first.
+func (w *rotatingWriter) prune() error {
+	backups, err := w.listBackups()
+	if err != nil {
+		return err
+	}
+
+	if w.cfg.MaxAgeDays > 0 {
+		cutoff := time.Now().Add(-time.Duration(w.cfg.MaxAgeDays) * 24 * time.Hour)
+		kept := backups[:0]
+		for _, b := range backups {
+			if b.modTime.Before(cutoff) {
+				if err := os.Remove(b.path); err != nil {
+					return err
+				}
+				continue
+			}
+			kept = append(kept, b)
+		}
+		backups = kept
+	}
+
+	if w.cfg.MaxBackups > 0 && len(backups) > w.cfg.MaxBackups {
+		for _, b := range backups[:len(backups)-w.cfg.MaxBackups] {
+			if err := os.Remove(b.path); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+type backupFile struct {
+	path    string
+	modTime time.Time
+}
+
+// listBackups returns the rotated files for cfg.Path, oldest first.
+func (w *rotatingWriter) listBackups() ([]backupFile, error) {
+	dir := filepath.Dir(w.cfg.Path)
+	prefix := filepath.Base(w.cfg.Path) + "."
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var backups []backupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		backups = append(backups, backupFile{
+			path:    filepath.Join(dir, name),
+			modTime: info.ModTime(),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.Before(backups[j].modTime) })
+	return backups, nil
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,107 @@
+package midlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingWriterRotatesPastMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "midgard.log")
+
+	w := newRotatingWriter(FileConfig{
+		Path:      path,
+		MaxSizeMB: 1,
+	})
+	const maxSize = 1024 * 1024
+	line := strings.Repeat("a", 1024) + "\n"
+
+	// Write enough lines to exceed the 1MB threshold at least twice.
+	lines := maxSize/len(line)*2 + 1
+	for i := 0; i < lines; i++ {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	backups, err := w.listBackups()
+	if err != nil {
+		t.Fatalf("listBackups: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Fatalf("expected at least one rotated backup file, got none")
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected active log file to exist: %v", err)
+	}
+}
+
+func TestRotatingWriterEnforcesMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "midgard.log")
+
+	w := newRotatingWriter(FileConfig{
+		Path:       path,
+		MaxSizeMB:  1,
+		MaxBackups: 2,
+	})
+
+	for i := 0; i < 5; i++ {
+		if err := w.openExisting(); err != nil {
+			t.Fatalf("openExisting: %v", err)
+		}
+		if err := w.rotate(); err != nil {
+			t.Fatalf("rotate %d: %v", i, err)
+		}
+		// Avoid collisions between backup filenames, which are timestamped
+		// to the nanosecond but rotations here happen faster than that.
+		time.Sleep(time.Millisecond)
+	}
+
+	backups, err := w.listBackups()
+	if err != nil {
+		t.Fatalf("listBackups: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected MaxBackups=2 to be enforced, got %d backups", len(backups))
+	}
+}
+
+func TestRotatingWriterPrunesByAge(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "midgard.log")
+
+	w := newRotatingWriter(FileConfig{
+		Path:       path,
+		MaxAgeDays: 1,
+	})
+
+	oldBackup := path + ".old"
+	if err := os.WriteFile(oldBackup, []byte("stale"), 0o644); err != nil {
+		t.Fatalf("seed old backup: %v", err)
+	}
+	oldTime := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(oldBackup, oldTime, oldTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+
+	freshBackup := path + ".fresh"
+	if err := os.WriteFile(freshBackup, []byte("fresh"), 0o644); err != nil {
+		t.Fatalf("seed fresh backup: %v", err)
+	}
+
+	if err := w.prune(); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+
+	if _, err := os.Stat(oldBackup); !os.IsNotExist(err) {
+		t.Fatalf("expected old backup to be pruned, stat err = %v", err)
+	}
+	if _, err := os.Stat(freshBackup); err != nil {
+		t.Fatalf("expected fresh backup to survive pruning: %v", err)
+	}
+}